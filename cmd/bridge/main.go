@@ -0,0 +1,240 @@
+// Command bridge runs the Dynatrace-to-CA-SDM webhook bridge, either as the
+// "server" that receives webhooks or as the "worker" that drains the redis
+// queue, depending on the subcommand.
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"log/slog"
+	"net/http"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/dlopes7/dynatrace-ca-sdm-bridge/pkg/config"
+	"github.com/dlopes7/dynatrace-ca-sdm-bridge/pkg/queue"
+	"github.com/dlopes7/dynatrace-ca-sdm-bridge/pkg/sdm"
+	"github.com/dlopes7/dynatrace-ca-sdm-bridge/pkg/storage"
+	"github.com/dlopes7/dynatrace-ca-sdm-bridge/pkg/webhook"
+	"github.com/urfave/cli/v2"
+)
+
+const configPath = "config.json"
+
+// setUpLogging points the default slog logger at the log file, as JSON lines
+// so it can be scraped by a log shipper, and returns the file for the caller
+// to close on shutdown, along with the *slog.LevelVar backing the handler so
+// a SIGHUP reload can change its level live.
+func setUpLogging(cfg config.Config) (*os.File, *slog.LevelVar) {
+	ex, err := os.Executable()
+	if err != nil {
+		panic(err)
+	}
+	exPath := filepath.Dir(ex)
+
+	folderPath := filepath.Join(exPath, "../log")
+	err = os.MkdirAll(folderPath, os.ModePerm)
+	if err != nil {
+		panic(err)
+	}
+	logPath := filepath.Join(folderPath, "webhook.log")
+	lf, err := os.OpenFile(logPath, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0660)
+	if err != nil {
+		log.Fatalf("Failed to open log file: %v", err)
+	}
+
+	var level slog.LevelVar
+	level.Set(parseLogLevel(cfg.LogLevel))
+
+	handler := slog.NewJSONHandler(lf, &slog.HandlerOptions{Level: &level})
+	slog.SetDefault(slog.New(handler))
+
+	return lf, &level
+}
+
+// parseLogLevel maps the CRITICAL/ERROR/WARNING/INFO/DEBUG levels used by
+// config.json to their closest slog.Level, defaulting to INFO.
+func parseLogLevel(level string) slog.Level {
+	switch strings.ToUpper(level) {
+	case "CRITICAL", "ERROR":
+		return slog.LevelError
+	case "WARNING":
+		return slog.LevelWarn
+	case "DEBUG":
+		return slog.LevelDebug
+	default:
+		return slog.LevelInfo
+	}
+}
+
+// runServer starts the webhook HTTP server. On SIGHUP it reloads config.json
+// without losing the listener: the SDM profiles/mapping and the log level
+// take effect immediately, while storage/queue settings still require a
+// restart. It waits for in-flight tickets to finish on SIGINT/SIGTERM
+// before exiting.
+func runServer(c *cli.Context) error {
+	cfg, err := config.Load(configPath)
+	if err != nil {
+		log.Fatal(err.Error())
+	}
+
+	lf, logLevel := setUpLogging(cfg)
+	defer lf.Close()
+
+	clients, err := newSDMClients(cfg)
+	if err != nil {
+		slog.Error("could not create the soap clients", "error", err.Error())
+		os.Exit(1)
+	}
+	sdmClients := sdm.NewClientSet(clients)
+
+	store, err := storage.New(cfg.Storage)
+	if err != nil {
+		slog.Error("could not set up storage", "error", err.Error())
+		os.Exit(1)
+	}
+
+	q, err := queue.New(cfg.Queue, queue.NewTaskHandler(sdmClients, config.DefaultProfile, store))
+	if err != nil {
+		slog.Error("could not set up queue", "error", err.Error())
+		os.Exit(1)
+	}
+
+	server := webhook.NewServer(cfg, sdmClients, store, q)
+
+	httpServer := &http.Server{
+		Addr:    fmt.Sprintf(":%d", cfg.ListenerPort),
+		Handler: server,
+	}
+
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	go func() {
+		for range hup {
+			slog.Info("received SIGHUP, reloading config", "path", configPath)
+			newCfg, err := config.Load(configPath)
+			if err != nil {
+				slog.Error("could not reload config", "path", configPath, "error", err.Error())
+				continue
+			}
+
+			newClients, err := newSDMClients(newCfg)
+			if err != nil {
+				slog.Error("could not rebuild the soap clients, keeping the previous ones", "error", err.Error())
+				continue
+			}
+
+			logLevel.Set(parseLogLevel(newCfg.LogLevel))
+			sdmClients.Set(newClients)
+			server.SetConfig(newCfg)
+		}
+	}()
+
+	errCh := make(chan error, 1)
+	go func() {
+		slog.Info("server started", "port", cfg.ListenerPort)
+		if err := httpServer.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			errCh <- err
+		}
+	}()
+
+	shutdown := make(chan os.Signal, 1)
+	signal.Notify(shutdown, syscall.SIGINT, syscall.SIGTERM)
+
+	select {
+	case err := <-errCh:
+		return err
+	case <-shutdown:
+	}
+
+	slog.Info("shutting down, waiting for in-flight tickets to finish")
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	if err := httpServer.Shutdown(ctx); err != nil {
+		return err
+	}
+
+	// The default in-memory queue keeps buffered and retrying tasks in this
+	// process, so draining it is part of "wait for in-flight tickets to
+	// finish"; the redis-backed queue survives a restart on its own and has
+	// nothing to drain here.
+	if drainer, ok := q.(interface{ Drain(context.Context) error }); ok {
+		if err := drainer.Drain(ctx); err != nil {
+			slog.Warn("queue did not drain before shutdown timeout, some tasks may be lost", "error", err.Error())
+		}
+	}
+
+	return nil
+}
+
+// runWorker consumes sdm:open_ticket/sdm:close_ticket tasks from the redis
+// queue and performs the SOAP calls.
+func runWorker(c *cli.Context) error {
+	cfg, err := config.Load(configPath)
+	if err != nil {
+		log.Fatal(err.Error())
+	}
+
+	lf, _ := setUpLogging(cfg)
+	defer lf.Close()
+
+	clients, err := newSDMClients(cfg)
+	if err != nil {
+		slog.Error("could not create the soap clients", "error", err.Error())
+		os.Exit(1)
+	}
+	sdmClients := sdm.NewClientSet(clients)
+
+	store, err := storage.New(cfg.Storage)
+	if err != nil {
+		slog.Error("could not set up storage", "error", err.Error())
+		os.Exit(1)
+	}
+
+	slog.Info("worker started", "redis_addr", cfg.Queue.Redis.Addr)
+	return queue.RunConsumer(cfg.Queue, queue.NewTaskHandler(sdmClients, config.DefaultProfile, store))
+}
+
+// newSDMClients dials a *sdm.Client for every profile in cfg.SDMProfiles,
+// all sharing cfg.Mapping to render ticket attributes.
+func newSDMClients(cfg config.Config) (map[string]*sdm.Client, error) {
+	clients := make(map[string]*sdm.Client)
+	for name, profile := range cfg.SDMProfiles() {
+		client, err := sdm.NewClient(profile.WSDL, profile.Username, profile.Password, cfg.Mapping)
+		if err != nil {
+			return nil, fmt.Errorf("profile %q: %w", name, err)
+		}
+		clients[name] = client
+	}
+	return clients, nil
+}
+
+func main() {
+	app := &cli.App{
+		Name:  "dynatrace-ca-sdm-bridge",
+		Usage: "bridges Dynatrace problem webhooks into CA SDM incidents",
+		Commands: []*cli.Command{
+			{
+				Name:   "server",
+				Usage:  "run the webhook HTTP server",
+				Action: runServer,
+			},
+			{
+				Name:   "worker",
+				Usage:  "consume queued tasks from redis and perform the SDM SOAP calls",
+				Action: runWorker,
+			},
+		},
+		Action: runServer,
+	}
+
+	if err := app.Run(os.Args); err != nil {
+		log.Fatal(err)
+	}
+}
@@ -0,0 +1,102 @@
+// Package config owns loading and validating the bridge's config.json.
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+
+	"github.com/dlopes7/dynatrace-ca-sdm-bridge/pkg/queue"
+	"github.com/dlopes7/dynatrace-ca-sdm-bridge/pkg/sdm"
+	"github.com/dlopes7/dynatrace-ca-sdm-bridge/pkg/storage"
+)
+
+// DefaultProfile is the profile name used when Profiles isn't set, or when a
+// webhook payload doesn't specify one.
+const DefaultProfile = "default"
+
+// Config is the bridge's config.json.
+type Config struct {
+	ListenerPort int            `json:"listenerPort"`
+	LogLevel     string         `json:"logLevel"`
+	SDMWSDL      string         `json:"SDMWSDL"`
+	SDMUsername  string         `json:"SDMUsername"`
+	SDMPassword  string         `json:"SDMPassword"`
+
+	// Profiles routes webhook payloads to different CA SDM tenants by name
+	// (see sdm.Problem.Profile). If empty, SDMWSDL/SDMUsername/SDMPassword
+	// become the single "default" profile.
+	Profiles map[string]sdm.Profile `json:"profiles"`
+	// Mapping drives the ticket field values sent to CA SDM.
+	Mapping sdm.Mapping `json:"mapping"`
+
+	Storage storage.Config `json:"storage"`
+	Queue   queue.Config   `json:"queue"`
+}
+
+// SDMProfiles returns the named CA SDM profiles to dial. If Profiles isn't
+// set, the legacy SDMWSDL/SDMUsername/SDMPassword fields become the single
+// "default" profile, so existing single-tenant config.json files keep
+// working unchanged.
+func (c Config) SDMProfiles() map[string]sdm.Profile {
+	if len(c.Profiles) > 0 {
+		return c.Profiles
+	}
+	return map[string]sdm.Profile{
+		DefaultProfile: {WSDL: c.SDMWSDL, Username: c.SDMUsername, Password: c.SDMPassword},
+	}
+}
+
+// Load reads and validates the configuration file at path.
+func Load(path string) (Config, error) {
+	var cfg Config
+
+	configFile, err := os.Open(path)
+	if err != nil {
+		return cfg, fmt.Errorf("could not read %s: %w", path, err)
+	}
+	defer configFile.Close()
+
+	byteValue, err := ioutil.ReadAll(configFile)
+	if err != nil {
+		return cfg, fmt.Errorf("could not read %s: %w", path, err)
+	}
+
+	if err := json.Unmarshal(byteValue, &cfg); err != nil {
+		return cfg, fmt.Errorf("could not parse %s: %w", path, err)
+	}
+
+	if err := cfg.Validate(); err != nil {
+		return cfg, err
+	}
+
+	return cfg, nil
+}
+
+// Validate checks that the fields required to start the bridge are set.
+func (c Config) Validate() error {
+	if c.ListenerPort == 0 {
+		return fmt.Errorf("listenerPort must be set")
+	}
+
+	if len(c.Profiles) == 0 {
+		if c.SDMWSDL == "" {
+			return fmt.Errorf("SDMWSDL must be set")
+		}
+		if c.SDMUsername == "" {
+			return fmt.Errorf("SDMUsername must be set")
+		}
+		return nil
+	}
+
+	for name, profile := range c.Profiles {
+		if profile.WSDL == "" {
+			return fmt.Errorf("profiles.%s.wsdl must be set", name)
+		}
+		if profile.Username == "" {
+			return fmt.Errorf("profiles.%s.username must be set", name)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,109 @@
+package queue
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/hibiken/asynq"
+)
+
+// AsynqQueue is a Queue backed by Redis via hibiken/asynq, so the bridge can
+// scale to multiple instances and survive process restarts without losing
+// work. Tasks are consumed by RunConsumer, run from the "worker" subcommand.
+type AsynqQueue struct {
+	client    *asynq.Client
+	inspector *asynq.Inspector
+	queueName string
+	maxRetry  int
+}
+
+// NewAsynqQueue connects to cfg.Redis.Addr and returns an AsynqQueue.
+func NewAsynqQueue(cfg Config) (*AsynqQueue, error) {
+	maxRetry := cfg.MaxRetry
+	if maxRetry <= 0 {
+		maxRetry = 10
+	}
+
+	redisOpt := asynqRedisOpt(cfg)
+
+	return &AsynqQueue{
+		client:    asynq.NewClient(redisOpt),
+		inspector: asynq.NewInspector(redisOpt),
+		queueName: "default",
+		maxRetry:  maxRetry,
+	}, nil
+}
+
+func (q *AsynqQueue) Enqueue(taskType string, payload interface{}) (string, error) {
+	b, err := json.Marshal(payload)
+	if err != nil {
+		return "", err
+	}
+
+	task := asynq.NewTask(taskType, b)
+	info, err := q.client.Enqueue(task, asynq.MaxRetry(q.maxRetry), asynq.Queue(q.queueName))
+	if err != nil {
+		return "", err
+	}
+
+	return info.ID, nil
+}
+
+func (q *AsynqQueue) JobStatus(jobID string) (*JobStatus, error) {
+	info, err := q.inspector.GetTaskInfo(q.queueName, jobID)
+	if err != nil {
+		return nil, err
+	}
+
+	status := &JobStatus{ID: info.ID, Type: info.Type, Retried: info.Retried}
+	switch info.State {
+	case asynq.TaskStateCompleted:
+		status.State = JobStateCompleted
+		status.Result = string(info.Result)
+	case asynq.TaskStateArchived:
+		status.State = JobStateFailed
+		status.Error = info.LastErr
+	default:
+		status.State = JobStatePending
+	}
+
+	return status, nil
+}
+
+// asynqTaskHandler adapts a TaskHandler to asynq.Handler.
+type asynqTaskHandler struct {
+	handler TaskHandler
+}
+
+func (h *asynqTaskHandler) ProcessTask(_ context.Context, task *asynq.Task) error {
+	_, err := h.handler(task.Type(), task.Payload())
+	return err
+}
+
+func asynqRedisOpt(cfg Config) asynq.RedisClientOpt {
+	return asynq.RedisClientOpt{
+		Addr:     cfg.Redis.Addr,
+		Password: cfg.Redis.Password,
+		DB:       cfg.Redis.DB,
+	}
+}
+
+// RunConsumer blocks consuming sdm:open_ticket/sdm:close_ticket tasks from
+// the redis queue described by cfg, relying on asynq's built-in exponential
+// backoff, max-retry and dead-letter queue instead of an in-handler retry
+// loop. It is run from the bridge's "worker" subcommand.
+func RunConsumer(cfg Config, handler TaskHandler) error {
+	concurrency := cfg.Concurrency
+	if concurrency <= 0 {
+		concurrency = 5
+	}
+
+	srv := asynq.NewServer(asynqRedisOpt(cfg), asynq.Config{Concurrency: concurrency})
+
+	mux := asynq.NewServeMux()
+	h := &asynqTaskHandler{handler: handler}
+	mux.Handle(TaskTypeOpenTicket, h)
+	mux.Handle(TaskTypeCloseTicket, h)
+
+	return srv.Run(mux)
+}
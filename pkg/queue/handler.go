@@ -0,0 +1,111 @@
+package queue
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+
+	"github.com/dlopes7/dynatrace-ca-sdm-bridge/pkg/sdm"
+	"github.com/dlopes7/dynatrace-ca-sdm-bridge/pkg/storage"
+)
+
+// OpenTicketPayload is the task payload for TaskTypeOpenTicket.
+type OpenTicketPayload struct {
+	Problem sdm.Problem `json:"problem"`
+}
+
+// CloseTicketPayload is the task payload for TaskTypeCloseTicket.
+type CloseTicketPayload struct {
+	Problem sdm.Problem `json:"problem"`
+}
+
+// NewTaskHandler returns the TaskHandler that performs the actual SDM SOAP
+// calls for a dequeued task and persists the result to store. clients holds
+// one *sdm.Client per configured profile name, and is re-read on every task
+// so a SIGHUP reload that swaps it takes effect immediately; a task whose
+// Problem.Profile is empty falls back to defaultProfile, and a task naming
+// an unknown profile fails with an error.
+func NewTaskHandler(clients *sdm.ClientSet, defaultProfile string, store storage.Storage) TaskHandler {
+	return func(taskType string, payload []byte) ([]byte, error) {
+		switch taskType {
+		case TaskTypeOpenTicket:
+			return handleOpenTicketTask(clients.Get(), defaultProfile, store, payload)
+		case TaskTypeCloseTicket:
+			return handleCloseTicketTask(clients.Get(), defaultProfile, store, payload)
+		default:
+			return nil, fmt.Errorf("unknown task type %q", taskType)
+		}
+	}
+}
+
+// selectClient picks the client for profile, falling back to
+// clients[defaultProfile] when profile is empty. If profile is empty and only
+// a single profile is configured, that profile is used regardless of its name, so a
+// single-tenant config.json doesn't have to name its one profile "default".
+func selectClient(clients map[string]*sdm.Client, defaultProfile string, profile string) (*sdm.Client, error) {
+	if profile == "" {
+		if len(clients) == 1 {
+			for _, client := range clients {
+				return client, nil
+			}
+		}
+		profile = defaultProfile
+	}
+	client, ok := clients[profile]
+	if !ok {
+		return nil, fmt.Errorf("unknown SDM profile %q", profile)
+	}
+	return client, nil
+}
+
+func handleOpenTicketTask(clients map[string]*sdm.Client, defaultProfile string, store storage.Storage, payload []byte) ([]byte, error) {
+	var p OpenTicketPayload
+	if err := json.Unmarshal(payload, &p); err != nil {
+		return nil, err
+	}
+
+	client, err := selectClient(clients, defaultProfile, p.Problem.Profile)
+	if err != nil {
+		return nil, err
+	}
+
+	title := fmt.Sprintf("Dynatrace - %s (Problem ID: %s, State: %s)", p.Problem.ProblemTitle, p.Problem.ProblemID, p.Problem.State)
+	ticket, err := client.OpenTicket(p.Problem, p.Problem.ProblemDetailsText, title)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := store.Put(p.Problem.ProblemID, ticket); err != nil {
+		return nil, err
+	}
+
+	slog.Info("opened ticket", "sdm_operation", "openTicket", "problem_id", p.Problem.ProblemID, "ticket_number", ticket.NewRequestNumber)
+	return []byte(ticket.NewRequestNumber), nil
+}
+
+func handleCloseTicketTask(clients map[string]*sdm.Client, defaultProfile string, store storage.Storage, payload []byte) ([]byte, error) {
+	var p CloseTicketPayload
+	if err := json.Unmarshal(payload, &p); err != nil {
+		return nil, err
+	}
+
+	client, err := selectClient(clients, defaultProfile, p.Problem.Profile)
+	if err != nil {
+		return nil, err
+	}
+
+	ticket, err := store.Get(p.Problem.ProblemID)
+	if err != nil {
+		return nil, err
+	}
+	if ticket == nil {
+		return nil, fmt.Errorf("could not find ticket number for problem %s", p.Problem.ProblemID)
+	}
+
+	if _, err := client.CloseTicket(p.Problem, ticket.NewRequestHandle); err != nil {
+		return nil, err
+	}
+
+	slog.Info("closed ticket", "sdm_operation", "closeTicket", "problem_id", p.Problem.ProblemID, "ticket_number", ticket.NewRequestNumber)
+	return []byte(ticket.NewRequestNumber), nil
+}
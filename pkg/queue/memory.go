@@ -0,0 +1,168 @@
+package queue
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var retriesInFlight = promauto.NewGauge(prometheus.GaugeOpts{
+	Name: "sdm_retries_in_flight",
+	Help: "Number of queued tasks currently waiting on a retry backoff.",
+})
+
+type memoryJob struct {
+	id       string
+	taskType string
+	payload  []byte
+	attempt  int
+}
+
+// MemoryQueue is a Queue with no external dependencies: tasks are processed
+// by goroutines in the same process. It is the default so the bridge still
+// works out of the box on a single node without Redis.
+type MemoryQueue struct {
+	handler  TaskHandler
+	maxRetry int
+
+	jobs chan memoryJob
+	wg   sync.WaitGroup // counts tasks not yet in a terminal state, for Drain
+
+	mu       sync.RWMutex
+	statuses map[string]*JobStatus
+	nextID   int64
+}
+
+// NewMemoryQueue starts cfg.Concurrency worker goroutines (default 5) that
+// run handler for every enqueued task.
+func NewMemoryQueue(cfg Config, handler TaskHandler) *MemoryQueue {
+	maxRetry := cfg.MaxRetry
+	if maxRetry <= 0 {
+		maxRetry = 10
+	}
+
+	q := &MemoryQueue{
+		handler:  handler,
+		maxRetry: maxRetry,
+		jobs:     make(chan memoryJob, 1000),
+		statuses: map[string]*JobStatus{},
+	}
+
+	workers := cfg.Concurrency
+	if workers <= 0 {
+		workers = 5
+	}
+	for i := 0; i < workers; i++ {
+		go q.runWorker()
+	}
+
+	return q
+}
+
+func (q *MemoryQueue) Enqueue(taskType string, payload interface{}) (string, error) {
+	b, err := json.Marshal(payload)
+	if err != nil {
+		return "", err
+	}
+
+	q.mu.Lock()
+	q.nextID++
+	id := fmt.Sprintf("mem-%d", q.nextID)
+	q.statuses[id] = &JobStatus{ID: id, Type: taskType, State: JobStatePending}
+	q.mu.Unlock()
+
+	q.wg.Add(1)
+	q.jobs <- memoryJob{id: id, taskType: taskType, payload: b}
+	return id, nil
+}
+
+// Drain blocks until every enqueued task has reached a terminal state
+// (completed or failed), or ctx is done first, so a graceful shutdown
+// doesn't drop tasks still buffered in q.jobs or waiting on a retry
+// backoff. Callers should check the returned error: ctx.Err() means some
+// tasks were still in flight when the deadline passed.
+func (q *MemoryQueue) Drain(ctx context.Context) error {
+	done := make(chan struct{})
+	go func() {
+		q.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// JobStatus returns a copy of the job's status, so the *JobStatus runWorker
+// keeps mutating under q.mu never escapes the lock into a caller's hands.
+func (q *MemoryQueue) JobStatus(jobID string) (*JobStatus, error) {
+	q.mu.RLock()
+	defer q.mu.RUnlock()
+
+	status, ok := q.statuses[jobID]
+	if !ok {
+		return nil, fmt.Errorf("job %s not found", jobID)
+	}
+	copied := *status
+	return &copied, nil
+}
+
+func (q *MemoryQueue) runWorker() {
+	for job := range q.jobs {
+		if job.attempt > 0 {
+			retriesInFlight.Dec()
+		}
+
+		start := time.Now()
+		result, err := q.handler(job.taskType, job.payload)
+		durationMS := time.Since(start).Milliseconds()
+
+		q.mu.Lock()
+		status := q.statuses[job.id]
+		if err != nil && job.attempt < q.maxRetry {
+			job.attempt++
+			status.Retried = job.attempt
+			q.mu.Unlock()
+			slog.Warn("task failed, retrying",
+				"task_type", job.taskType, "job_id", job.id, "attempt", job.attempt,
+				"max_retry", q.maxRetry, "duration_ms", durationMS, "error", err.Error())
+			retriesInFlight.Inc()
+			j := job
+			time.AfterFunc(memoryQueueBackoff(job.attempt), func() { q.jobs <- j })
+			continue
+		}
+
+		if err != nil {
+			status.State = JobStateFailed
+			status.Error = err.Error()
+			slog.Error("task failed, giving up",
+				"task_type", job.taskType, "job_id", job.id, "attempt", job.attempt,
+				"duration_ms", durationMS, "error", err.Error())
+		} else {
+			status.State = JobStateCompleted
+			status.Result = string(result)
+			slog.Info("task completed",
+				"task_type", job.taskType, "job_id", job.id, "attempt", job.attempt, "duration_ms", durationMS)
+		}
+		q.mu.Unlock()
+		q.wg.Done()
+	}
+}
+
+// memoryQueueBackoff waits attempt² seconds before a retry, capped at a minute.
+func memoryQueueBackoff(attempt int) time.Duration {
+	d := time.Duration(attempt*attempt) * time.Second
+	if d > time.Minute {
+		return time.Minute
+	}
+	return d
+}
@@ -0,0 +1,111 @@
+package queue
+
+import (
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func waitForState(t *testing.T, q *MemoryQueue, jobID string, want JobState) *JobStatus {
+	t.Helper()
+	deadline := time.Now().Add(10 * time.Second)
+	for time.Now().Before(deadline) {
+		status, err := q.JobStatus(jobID)
+		if err != nil {
+			t.Fatalf("JobStatus returned error: %v", err)
+		}
+		if status.State == want {
+			return status
+		}
+		time.Sleep(time.Millisecond)
+	}
+	t.Fatalf("job %s did not reach state %s in time", jobID, want)
+	return nil
+}
+
+func TestMemoryQueueRetriesUntilSuccess(t *testing.T) {
+	var attempts int32
+	handler := TaskHandler(func(taskType string, payload []byte) ([]byte, error) {
+		if atomic.AddInt32(&attempts, 1) < 3 {
+			return nil, fmt.Errorf("transient failure")
+		}
+		return []byte("ok"), nil
+	})
+
+	q := NewMemoryQueue(Config{Concurrency: 1}, handler)
+	jobID, err := q.Enqueue(TaskTypeOpenTicket, OpenTicketPayload{})
+	if err != nil {
+		t.Fatalf("Enqueue returned error: %v", err)
+	}
+
+	status := waitForState(t, q, jobID, JobStateCompleted)
+	if status.Result != "ok" {
+		t.Errorf("Result = %q, want %q", status.Result, "ok")
+	}
+	if status.Retried != 2 {
+		t.Errorf("Retried = %d, want 2", status.Retried)
+	}
+}
+
+func TestMemoryQueueGivesUpAfterMaxRetry(t *testing.T) {
+	handler := TaskHandler(func(taskType string, payload []byte) ([]byte, error) {
+		return nil, fmt.Errorf("permanent failure")
+	})
+
+	q := NewMemoryQueue(Config{Concurrency: 1, MaxRetry: 1}, handler)
+	jobID, err := q.Enqueue(TaskTypeCloseTicket, CloseTicketPayload{})
+	if err != nil {
+		t.Fatalf("Enqueue returned error: %v", err)
+	}
+
+	status := waitForState(t, q, jobID, JobStateFailed)
+	if status.Retried != 1 {
+		t.Errorf("Retried = %d, want 1", status.Retried)
+	}
+	if status.Error == "" {
+		t.Error("Error is empty, want the handler's last error")
+	}
+}
+
+// TestMemoryQueueRetryPreservesJobIdentity enqueues several jobs that each
+// retry once, so their backoff timers fire concurrently with runWorker
+// processing later jobs, and checks every job completes with its own
+// payload rather than one re-enqueued under another job's identity. Run
+// with -race: this is the scenario the time.AfterFunc loop-variable capture
+// bug corrupted.
+func TestMemoryQueueRetryPreservesJobIdentity(t *testing.T) {
+	const jobs = 10
+	var attempts sync.Map // payload -> attempt count
+
+	handler := TaskHandler(func(taskType string, payload []byte) ([]byte, error) {
+		key := string(payload)
+		n, _ := attempts.LoadOrStore(key, new(int32))
+		if atomic.AddInt32(n.(*int32), 1) == 1 {
+			return nil, fmt.Errorf("retry me")
+		}
+		return payload, nil
+	})
+
+	q := NewMemoryQueue(Config{Concurrency: 1}, handler)
+
+	jobIDs := make([]string, jobs)
+	want := make(map[string]string, jobs)
+	for i := 0; i < jobs; i++ {
+		payload := fmt.Sprintf("job-%d", i)
+		id, err := q.Enqueue(TaskTypeOpenTicket, payload)
+		if err != nil {
+			t.Fatalf("Enqueue returned error: %v", err)
+		}
+		jobIDs[i] = id
+		want[id] = fmt.Sprintf("%q", payload)
+	}
+
+	for _, id := range jobIDs {
+		status := waitForState(t, q, id, JobStateCompleted)
+		if status.Result != want[id] {
+			t.Errorf("job %s Result = %q, want %q", id, status.Result, want[id])
+		}
+	}
+}
@@ -0,0 +1,75 @@
+// Package queue enqueues sdm:open_ticket/sdm:close_ticket tasks so the
+// webhook handler can return immediately instead of blocking on the CA SDM
+// SOAP calls, and lets callers poll a job's state afterwards.
+package queue
+
+import "fmt"
+
+const (
+	// TaskTypeOpenTicket is the task type enqueued when a Dynatrace problem opens.
+	TaskTypeOpenTicket = "sdm:open_ticket"
+	// TaskTypeCloseTicket is the task type enqueued when a Dynatrace problem resolves.
+	TaskTypeCloseTicket = "sdm:close_ticket"
+)
+
+// JobState is the lifecycle state of a queued task.
+type JobState string
+
+const (
+	JobStatePending   JobState = "pending"
+	JobStateCompleted JobState = "completed"
+	JobStateFailed    JobState = "failed"
+)
+
+// JobStatus is returned by /jobs/{id} so callers can poll a task that was
+// enqueued by the webhook handler instead of waiting on the HTTP connection.
+type JobStatus struct {
+	ID      string   `json:"id"`
+	Type    string   `json:"type"`
+	State   JobState `json:"state"`
+	Result  string   `json:"result,omitempty"`
+	Error   string   `json:"error,omitempty"`
+	Retried int      `json:"retried"`
+}
+
+// TaskHandler performs the work for a dequeued task and returns a result.
+// It is shared between the in-process MemoryQueue and the asynq-backed
+// worker subcommand; see NewTaskHandler. MemoryQueue stores the result
+// alongside the job status; the asynq consumer discards it, so JobStatus
+// reports no Result for redis-queued jobs.
+type TaskHandler func(taskType string, payload []byte) ([]byte, error)
+
+// Queue enqueues tasks and lets callers look their state back up by job ID.
+type Queue interface {
+	Enqueue(taskType string, payload interface{}) (jobID string, err error)
+	JobStatus(jobID string) (*JobStatus, error)
+}
+
+// Config selects and configures a Queue implementation.
+type Config struct {
+	Type        string      `json:"type"` // "memory" (default) or "redis"
+	MaxRetry    int         `json:"maxRetry"`
+	Concurrency int         `json:"concurrency"`
+	Redis       RedisConfig `json:"redis"`
+}
+
+// RedisConfig configures the asynq-backed Queue implementation.
+type RedisConfig struct {
+	Addr     string `json:"addr"`
+	Password string `json:"password"`
+	DB       int    `json:"db"`
+}
+
+// New builds the Queue implementation selected by cfg.Type. handler is only
+// used by the in-process MemoryQueue: the redis-backed queue is consumed by
+// the separate "worker" subcommand via RunConsumer instead.
+func New(cfg Config, handler TaskHandler) (Queue, error) {
+	switch cfg.Type {
+	case "", "memory":
+		return NewMemoryQueue(cfg, handler), nil
+	case "redis":
+		return NewAsynqQueue(cfg)
+	default:
+		return nil, fmt.Errorf("unknown queue type %q, options are memory, redis", cfg.Type)
+	}
+}
@@ -0,0 +1,307 @@
+// Package sdm wraps the CA Service Desk Manager SOAP API used to open and
+// close tickets for Dynatrace problems.
+package sdm
+
+import (
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/tiaguinho/gosoap"
+)
+
+var (
+	ticketsOpenedTotal = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "sdm_tickets_opened_total",
+		Help: "Total number of CA SDM tickets opened.",
+	})
+	ticketsClosedTotal = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "sdm_tickets_closed_total",
+		Help: "Total number of CA SDM tickets closed.",
+	})
+	soapErrorsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "sdm_soap_errors_total",
+		Help: "Total number of failed CA SDM SOAP calls, by operation.",
+	}, []string{"op"})
+	soapCallDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name: "sdm_soap_call_duration_seconds",
+		Help: "Duration of CA SDM SOAP calls, by operation.",
+	}, []string{"op"})
+)
+
+// pingTTL caches the readiness probe result so Healthy doesn't log in to CA
+// SDM on every /readyz check.
+const pingTTL = 30 * time.Second
+
+// Problem is the Dynatrace problem payload delivered by the webhook.
+type Problem struct {
+	Pcat               string `json:"Pcat"`
+	ProblemID          string `json:"ProblemID"`
+	State              string `json:"State"`
+	Severity           string `json:"Severity"`
+	ProblemDetailsText string `json:"ProblemDetailsText"`
+	ProblemTitle       string `json:"ProblemTitle"`
+
+	// Profile selects which named CA SDM profile (see config.Config.Profiles)
+	// this problem should be routed to. Empty means the default profile.
+	Profile string `json:"Profile"`
+}
+
+func (p Problem) String() string {
+	return fmt.Sprintf("ProblemID: %s, State: %s, Title: %s, Details: %s", p.ProblemID, p.State, p.ProblemTitle, p.ProblemDetailsText)
+}
+
+// LoginResponse is the response from CA SDM login operation
+type LoginResponse struct {
+	LoginReturn string `xml:"loginReturn"`
+}
+
+// CreateRequestRequest is the request for creating an incident
+type CreateRequestRequest struct {
+	Sid              string `xml:"sid"`
+	CreatorHandle    string `xml:"creatorHandle"`
+	AttrVals         string `xml:"attrVals"`
+	PropertyValues   string `xml:"propertyValues"`
+	Template         string `xml:"template"`
+	Attributes       string `xml:"attributes"`
+	NewRequestHandle string `xml:"newRequestHandle"`
+	NewRequestNumber string `xml:"newRequestNumber"`
+}
+
+// GetHandleForUserIDResponse is used for subsequent operations
+type GetHandleForUserIDResponse struct {
+	GetHandleForUserIDReturn string `xml:"getHandleForUseridReturn"`
+}
+
+type CreateRequestResponse struct {
+	// CreateRequestReturn string `xml:"createRequestReturn"`
+	NewRequestHandle string `xml:"newRequestHandle"`
+	NewRequestNumber string `xml:"newRequestNumber"`
+}
+
+type UpdateObjectResponse struct {
+	UpdateObjectReturn string `xml:"updateObjectReturn"`
+}
+
+// Client is a CA SDM SOAP client that knows how to open and close requests.
+type Client struct {
+	soap     *gosoap.Client
+	username string
+	password string
+	mapping  Mapping
+
+	pingMu      sync.Mutex
+	pingErr     error
+	pingChecked time.Time
+}
+
+// NewClient dials wsdl and returns a Client that authenticates as username.
+// mapping drives the ticket attribute values OpenTicket and CloseTicket send
+// to CA SDM.
+func NewClient(wsdl string, username string, password string, mapping Mapping) (*Client, error) {
+	soap, err := gosoap.SoapClient(wsdl)
+	if err != nil {
+		return nil, fmt.Errorf("could not create the Soap Client with WSDL: %w", err)
+	}
+
+	return &Client{soap: soap, username: username, password: password, mapping: mapping}, nil
+}
+
+// call invokes the named SOAP operation, recording its duration and, on
+// failure, incrementing sdm_soap_errors_total for op.
+func (c *Client) call(op string, fn func() error) error {
+	start := time.Now()
+	err := fn()
+	soapCallDuration.WithLabelValues(op).Observe(time.Since(start).Seconds())
+	if err != nil {
+		soapErrorsTotal.WithLabelValues(op).Inc()
+	}
+	return err
+}
+
+func (c *Client) login() (*LoginResponse, error) {
+	params := gosoap.Params{
+		"username": c.username,
+		"password": c.password,
+	}
+
+	if err := c.call("login", func() error { return c.soap.Call("login", params) }); err != nil {
+		return nil, err
+	}
+
+	l := LoginResponse{}
+	if err := c.soap.Unmarshal(&l); err != nil {
+		return nil, err
+	}
+
+	return &l, nil
+}
+
+func (c *Client) getHandle(sid string, username string) (*GetHandleForUserIDResponse, error) {
+	p := gosoap.Params{
+		"sid":    sid,
+		"userID": username,
+	}
+
+	if err := c.call("getHandleForUserid", func() error { return c.soap.Call("getHandleForUserid", p) }); err != nil {
+		return nil, err
+	}
+
+	g := GetHandleForUserIDResponse{}
+	if err := c.soap.Unmarshal(&g); err != nil {
+		return nil, err
+	}
+
+	return &g, nil
+}
+
+func (c *Client) updateObject(sid string, objectHandle string, attrVals []gosoap.Params, attributes []gosoap.Params) (*UpdateObjectResponse, error) {
+	params := gosoap.Params{
+		"sid":          sid,
+		"objectHandle": objectHandle,
+		"attrVals":     attrVals,
+		"attributes":   attributes,
+	}
+
+	if err := c.call("updateObject", func() error { return c.soap.Call("updateObject", params) }); err != nil {
+		return nil, err
+	}
+
+	r := UpdateObjectResponse{}
+	if err := c.soap.Unmarshal(&r); err != nil {
+		return nil, err
+	}
+
+	return &r, nil
+}
+
+func (c *Client) createRequest(sid string,
+	creatorHandle string,
+	attrVals []gosoap.Params,
+	propertyValues []gosoap.Params,
+	template string,
+	attributes []gosoap.Params,
+	newRequestHandle string,
+	newRequestNumber string) (*CreateRequestResponse, error) {
+
+	params := gosoap.Params{
+		"sid":              sid,
+		"creatorHandle":    creatorHandle,
+		"attrVals":         attrVals,
+		"propertyValues":   propertyValues,
+		"template":         template,
+		"attributes":       attributes,
+		"newRequestHandle": newRequestHandle,
+		"newRequestNumber": newRequestNumber,
+	}
+
+	if err := c.call("createRequest", func() error { return c.soap.Call("createRequest", params) }); err != nil {
+		return nil, err
+	}
+
+	r := CreateRequestResponse{}
+	if err := c.soap.Unmarshal(&r); err != nil {
+		return nil, err
+	}
+
+	return &r, nil
+}
+
+// CloseTicket logs in and marks objectHandle resolved, using problem to
+// render the configured CloseAttributes mapping.
+func (c *Client) CloseTicket(problem Problem, objectHandle string) (*UpdateObjectResponse, error) {
+	l, err := c.login()
+	if err != nil {
+		slog.Error("could not login", "sdm_operation", "closeTicket", "error", err.Error())
+		return nil, err
+	}
+
+	attrValues, attributes, err := c.mapping.closeAttributes(problem)
+	if err != nil {
+		slog.Error("could not render mapping", "sdm_operation", "closeTicket", "error", err.Error())
+		return nil, err
+	}
+
+	r, err := c.updateObject(l.LoginReturn, objectHandle, attrValues, attributes)
+	if err != nil {
+		slog.Error("could not update request", "sdm_operation", "closeTicket", "error", err.Error())
+		return nil, err
+	}
+
+	ticketsClosedTotal.Inc()
+	return r, nil
+}
+
+// OpenTicket logs in and creates a new incident from the given problem,
+// description and summary. urgency/impact/group/type (and any per-Pcat
+// overrides) come from the configured OpenAttributes mapping.
+func (c *Client) OpenTicket(problem Problem, description string, summary string) (*CreateRequestResponse, error) {
+	l, err := c.login()
+	if err != nil {
+		slog.Error("could not login", "sdm_operation", "openTicket", "error", err.Error())
+		return nil, err
+	}
+
+	h, err := c.getHandle(l.LoginReturn, c.username)
+	if err != nil {
+		slog.Error("could not get handle", "sdm_operation", "openTicket", "error", err.Error())
+		return nil, err
+	}
+
+	attrs := []gosoap.Params{
+		{"string": "customer"},
+		{"string": h.GetHandleForUserIDReturn},
+
+		{"string": "category"},
+		{"string": fmt.Sprintf("pcat:%s", problem.Pcat)},
+
+		{"string": "description"},
+		{"string": description},
+
+		{"string": "summary"},
+		{"string": summary},
+	}
+
+	mapped, err := c.mapping.openAttributes(problem)
+	if err != nil {
+		slog.Error("could not render mapping", "sdm_operation", "openTicket", "error", err.Error())
+		return nil, err
+	}
+	attrs = append(attrs, mapped...)
+
+	r, err := c.createRequest(l.LoginReturn,
+		h.GetHandleForUserIDReturn,
+		attrs,
+		[]gosoap.Params{},
+		"",
+		[]gosoap.Params{},
+		"",
+		"")
+
+	if err != nil {
+		slog.Error("could not create request", "sdm_operation", "openTicket", "error", err.Error())
+		return nil, err
+	}
+
+	ticketsOpenedTotal.Inc()
+	return r, nil
+}
+
+// Healthy performs a cached SOAP login probe, so /readyz can report that CA
+// SDM is reachable without logging in on every Kubernetes readiness check.
+func (c *Client) Healthy() error {
+	c.pingMu.Lock()
+	defer c.pingMu.Unlock()
+
+	if time.Since(c.pingChecked) < pingTTL {
+		return c.pingErr
+	}
+
+	_, err := c.login()
+	c.pingErr = err
+	c.pingChecked = time.Now()
+	return err
+}
@@ -0,0 +1,32 @@
+package sdm
+
+import "sync"
+
+// ClientSet holds the live *Client per configured profile and can be swapped
+// wholesale, e.g. when a SIGHUP rebuilds every profile's client from a
+// reloaded config.json. Sharing a single ClientSet between the webhook
+// server and the queue's TaskHandler means a reload takes effect for both
+// without either holding a stale map.
+type ClientSet struct {
+	mu      sync.RWMutex
+	clients map[string]*Client
+}
+
+// NewClientSet wraps an initial set of per-profile clients.
+func NewClientSet(clients map[string]*Client) *ClientSet {
+	return &ClientSet{clients: clients}
+}
+
+// Set replaces every client, e.g. after reloading config.json.
+func (s *ClientSet) Set(clients map[string]*Client) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.clients = clients
+}
+
+// Get returns the current profile-to-client map.
+func (s *ClientSet) Get() map[string]*Client {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.clients
+}
@@ -0,0 +1,123 @@
+package sdm
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"text/template"
+
+	"github.com/tiaguinho/gosoap"
+)
+
+// Profile is a named CA SDM tenant: its own SOAP endpoint and credentials.
+// Defining more than one Profile turns the bridge into a multi-tenant
+// router, picking between them per Problem.Profile.
+type Profile struct {
+	WSDL     string `json:"wsdl"`
+	Username string `json:"username"`
+	Password string `json:"password"`
+}
+
+// Mapping drives the attribute values sent to CA SDM when opening or closing
+// a ticket. Each value is a Go text/template string evaluated against the
+// incoming Problem, e.g. `"urgency": "{{ if eq .Severity \"CRITICAL\" }}1{{ else }}3{{ end }}"`.
+// PcatOverrides layers additional templates on top of OpenAttributes for a
+// given Problem.Pcat, so different Dynatrace problem categories can route to
+// different SDM groups or request templates.
+type Mapping struct {
+	OpenAttributes  map[string]string            `json:"openAttributes"`
+	CloseAttributes map[string]string            `json:"closeAttributes"`
+	PcatOverrides   map[string]map[string]string `json:"pcatOverrides"`
+}
+
+// DefaultMapping holds the attribute values this bridge always sent before
+// the mapping became configuration-driven. It is applied whenever
+// config.json has no "mapping" section, so deployments upgrading from an
+// older config.json keep opening and closing tickets exactly as before.
+var DefaultMapping = Mapping{
+	OpenAttributes: map[string]string{
+		"urgency": "2",
+		"impact":  "4",
+		"group":   "5FA1B7BE4CFA2E4C9B19E115AE49A642",
+		"type":    "crt:182",
+	},
+	CloseAttributes: map[string]string{
+		"status":    "RE",
+		"rootcause": "rc:400174",
+	},
+}
+
+// withDefaults fills OpenAttributes/CloseAttributes from DefaultMapping
+// whenever they are unset, so an unconfigured Mapping behaves like the
+// hardcoded values it replaced.
+func (m Mapping) withDefaults() Mapping {
+	if len(m.OpenAttributes) == 0 {
+		m.OpenAttributes = DefaultMapping.OpenAttributes
+	}
+	if len(m.CloseAttributes) == 0 {
+		m.CloseAttributes = DefaultMapping.CloseAttributes
+	}
+	return m
+}
+
+// openAttributes renders OpenAttributes, layering any PcatOverrides for
+// problem.Pcat on top, and returns the flattened attrVals pairs expected by
+// createRequest.
+func (m Mapping) openAttributes(problem Problem) ([]gosoap.Params, error) {
+	m = m.withDefaults()
+	merged := mergeTemplates(m.OpenAttributes, m.PcatOverrides[problem.Pcat])
+	return renderTemplates(merged, problem)
+}
+
+// closeAttributes renders CloseAttributes and returns both the flattened
+// attrVals pairs and the bare field names expected by updateObject.
+func (m Mapping) closeAttributes(problem Problem) (attrVals []gosoap.Params, attributes []gosoap.Params, err error) {
+	m = m.withDefaults()
+	attrVals, err = renderTemplates(m.CloseAttributes, problem)
+	if err != nil {
+		return nil, nil, err
+	}
+	for i := 0; i < len(attrVals); i += 2 {
+		attributes = append(attributes, attrVals[i])
+	}
+	return attrVals, attributes, nil
+}
+
+func mergeTemplates(base, overrides map[string]string) map[string]string {
+	merged := make(map[string]string, len(base)+len(overrides))
+	for field, tmpl := range base {
+		merged[field] = tmpl
+	}
+	for field, tmpl := range overrides {
+		merged[field] = tmpl
+	}
+	return merged
+}
+
+// renderTemplates evaluates each template against problem and returns the
+// {field, value} pairs sorted by field name, so repeated calls build an
+// identical SOAP request.
+func renderTemplates(templates map[string]string, problem Problem) ([]gosoap.Params, error) {
+	fields := make([]string, 0, len(templates))
+	for field := range templates {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+
+	values := make([]gosoap.Params, 0, len(fields)*2)
+	for _, field := range fields {
+		tmpl, err := template.New(field).Parse(templates[field])
+		if err != nil {
+			return nil, fmt.Errorf("could not parse mapping template for %q: %w", field, err)
+		}
+
+		var buf bytes.Buffer
+		if err := tmpl.Execute(&buf, problem); err != nil {
+			return nil, fmt.Errorf("could not render mapping template for %q: %w", field, err)
+		}
+
+		values = append(values, gosoap.Params{"string": field}, gosoap.Params{"string": buf.String()})
+	}
+
+	return values, nil
+}
@@ -0,0 +1,102 @@
+package sdm
+
+import (
+	"testing"
+
+	"github.com/tiaguinho/gosoap"
+)
+
+// fieldValues turns the {field}, {value} pairs renderTemplates produces back
+// into a map, so tests can assert on it regardless of field order.
+func fieldValues(t *testing.T, pairs []gosoap.Params) map[string]string {
+	t.Helper()
+	if len(pairs)%2 != 0 {
+		t.Fatalf("got %d params, want an even number of field/value pairs", len(pairs))
+	}
+
+	values := make(map[string]string, len(pairs)/2)
+	for i := 0; i < len(pairs); i += 2 {
+		field, ok := pairs[i]["string"].(string)
+		if !ok {
+			t.Fatalf("pair %d field is not a string: %#v", i, pairs[i])
+		}
+		value, ok := pairs[i+1]["string"].(string)
+		if !ok {
+			t.Fatalf("pair %d value is not a string: %#v", i+1, pairs[i+1])
+		}
+		values[field] = value
+	}
+	return values
+}
+
+func assertContains(t *testing.T, got map[string]string, want map[string]string) {
+	t.Helper()
+	for field, value := range want {
+		if got[field] != value {
+			t.Errorf("field %q = %q, want %q", field, got[field], value)
+		}
+	}
+}
+
+func TestMappingOpenAttributesDefaults(t *testing.T) {
+	var m Mapping // zero value: no "mapping" section in config.json
+
+	got, err := m.openAttributes(Problem{Pcat: "network"})
+	if err != nil {
+		t.Fatalf("openAttributes returned error: %v", err)
+	}
+
+	assertContains(t, fieldValues(t, got), map[string]string{
+		"urgency": "2",
+		"impact":  "4",
+		"group":   "5FA1B7BE4CFA2E4C9B19E115AE49A642",
+		"type":    "crt:182",
+	})
+}
+
+func TestMappingCloseAttributesDefaults(t *testing.T) {
+	var m Mapping
+
+	attrVals, attributes, err := m.closeAttributes(Problem{})
+	if err != nil {
+		t.Fatalf("closeAttributes returned error: %v", err)
+	}
+
+	assertContains(t, fieldValues(t, attrVals), map[string]string{
+		"status":    "RE",
+		"rootcause": "rc:400174",
+	})
+
+	if len(attributes) != 2 {
+		t.Fatalf("got %d bare attribute names, want 2", len(attributes))
+	}
+}
+
+func TestMappingOpenAttributesConfigured(t *testing.T) {
+	m := Mapping{
+		OpenAttributes: map[string]string{
+			"urgency": `{{ if eq .Severity "CRITICAL" }}1{{ else }}3{{ end }}`,
+		},
+		PcatOverrides: map[string]map[string]string{
+			"hardware": {"group": "hardware-group"},
+		},
+	}
+
+	got, err := m.openAttributes(Problem{Severity: "CRITICAL", Pcat: "hardware"})
+	if err != nil {
+		t.Fatalf("openAttributes returned error: %v", err)
+	}
+
+	assertContains(t, fieldValues(t, got), map[string]string{
+		"urgency": "1",
+		"group":   "hardware-group",
+	})
+}
+
+func TestMappingOpenAttributesInvalidTemplate(t *testing.T) {
+	m := Mapping{OpenAttributes: map[string]string{"urgency": "{{ .NoSuchField }}"}}
+
+	if _, err := m.openAttributes(Problem{}); err == nil {
+		t.Fatal("expected an error for a template referencing an unknown field")
+	}
+}
@@ -0,0 +1,147 @@
+package storage
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/dlopes7/dynatrace-ca-sdm-bridge/pkg/sdm"
+)
+
+// JSONConfig configures the file-backed Storage implementation.
+type JSONConfig struct {
+	Path string `json:"path"`
+}
+
+// fileContents is the on-disk layout of the JSON storage file.
+type fileContents struct {
+	Problems map[string]sdm.CreateRequestResponse `json:"problems"`
+}
+
+// JSONStorage is a Storage backed by a single JSON file. Reads and writes
+// are guarded by a RWMutex so concurrent webhooks cannot corrupt the file,
+// and writes are made atomic by writing to a temp file and renaming it over
+// the real path.
+type JSONStorage struct {
+	path string
+	mu   sync.RWMutex
+}
+
+// NewJSONStorage returns a JSONStorage persisting to cfg.Path, defaulting to
+// storage.json in the working directory when unset.
+func NewJSONStorage(cfg JSONConfig) (*JSONStorage, error) {
+	path := cfg.Path
+	if path == "" {
+		path = "storage.json"
+	}
+	return &JSONStorage{path: path}, nil
+}
+
+func (s *JSONStorage) read() (fileContents, error) {
+	data := fileContents{Problems: map[string]sdm.CreateRequestResponse{}}
+
+	byteValue, err := ioutil.ReadFile(s.path)
+	if os.IsNotExist(err) {
+		return data, nil
+	}
+	if err != nil {
+		return data, err
+	}
+
+	if len(byteValue) == 0 {
+		return data, nil
+	}
+
+	if err := json.Unmarshal(byteValue, &data); err != nil {
+		return data, err
+	}
+	if data.Problems == nil {
+		data.Problems = map[string]sdm.CreateRequestResponse{}
+	}
+	return data, nil
+}
+
+func (s *JSONStorage) write(data fileContents) error {
+	file, err := json.MarshalIndent(data, "", " ")
+	if err != nil {
+		return err
+	}
+
+	dir := filepath.Dir(s.path)
+	tmp, err := ioutil.TempFile(dir, ".storage-*.json")
+	if err != nil {
+		return err
+	}
+	tmpPath := tmp.Name()
+
+	if _, err := tmp.Write(file); err != nil {
+		tmp.Close()
+		os.Remove(tmpPath)
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmpPath)
+		return err
+	}
+
+	return os.Rename(tmpPath, s.path)
+}
+
+func (s *JSONStorage) Get(problemID string) (*sdm.CreateRequestResponse, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	data, err := s.read()
+	if err != nil {
+		return nil, err
+	}
+
+	if ticket, ok := data.Problems[problemID]; ok {
+		return &ticket, nil
+	}
+	return nil, nil
+}
+
+func (s *JSONStorage) Put(problemID string, ticket *sdm.CreateRequestResponse) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	data, err := s.read()
+	if err != nil {
+		return err
+	}
+
+	data.Problems[problemID] = *ticket
+	return s.write(data)
+}
+
+func (s *JSONStorage) Delete(problemID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	data, err := s.read()
+	if err != nil {
+		return err
+	}
+
+	delete(data.Problems, problemID)
+	return s.write(data)
+}
+
+func (s *JSONStorage) List() ([]string, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	data, err := s.read()
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make([]string, 0, len(data.Problems))
+	for id := range data.Problems {
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
@@ -0,0 +1,81 @@
+package storage
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/dlopes7/dynatrace-ca-sdm-bridge/pkg/sdm"
+)
+
+func TestJSONStoragePutGetDelete(t *testing.T) {
+	s, err := NewJSONStorage(JSONConfig{Path: filepath.Join(t.TempDir(), "storage.json")})
+	if err != nil {
+		t.Fatalf("NewJSONStorage returned error: %v", err)
+	}
+
+	if ticket, err := s.Get("PROBLEM-1"); err != nil || ticket != nil {
+		t.Fatalf("Get on an empty store = (%v, %v), want (nil, nil)", ticket, err)
+	}
+
+	want := &sdm.CreateRequestResponse{NewRequestHandle: "handle-1", NewRequestNumber: "REQ-1"}
+	if err := s.Put("PROBLEM-1", want); err != nil {
+		t.Fatalf("Put returned error: %v", err)
+	}
+
+	got, err := s.Get("PROBLEM-1")
+	if err != nil {
+		t.Fatalf("Get returned error: %v", err)
+	}
+	if got == nil || *got != *want {
+		t.Fatalf("Get = %+v, want %+v", got, want)
+	}
+
+	ids, err := s.List()
+	if err != nil {
+		t.Fatalf("List returned error: %v", err)
+	}
+	if len(ids) != 1 || ids[0] != "PROBLEM-1" {
+		t.Fatalf("List = %v, want [PROBLEM-1]", ids)
+	}
+
+	if err := s.Delete("PROBLEM-1"); err != nil {
+		t.Fatalf("Delete returned error: %v", err)
+	}
+	if ticket, err := s.Get("PROBLEM-1"); err != nil || ticket != nil {
+		t.Fatalf("Get after Delete = (%v, %v), want (nil, nil)", ticket, err)
+	}
+}
+
+func TestJSONStoragePersistsAcrossInstances(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "storage.json")
+
+	first, err := NewJSONStorage(JSONConfig{Path: path})
+	if err != nil {
+		t.Fatalf("NewJSONStorage returned error: %v", err)
+	}
+	if err := first.Put("PROBLEM-1", &sdm.CreateRequestResponse{NewRequestNumber: "REQ-1"}); err != nil {
+		t.Fatalf("Put returned error: %v", err)
+	}
+
+	second, err := NewJSONStorage(JSONConfig{Path: path})
+	if err != nil {
+		t.Fatalf("NewJSONStorage returned error: %v", err)
+	}
+	got, err := second.Get("PROBLEM-1")
+	if err != nil {
+		t.Fatalf("Get returned error: %v", err)
+	}
+	if got == nil || got.NewRequestNumber != "REQ-1" {
+		t.Fatalf("Get = %+v, want NewRequestNumber REQ-1", got)
+	}
+}
+
+func TestNewJSONStorageDefaultPath(t *testing.T) {
+	s, err := NewJSONStorage(JSONConfig{})
+	if err != nil {
+		t.Fatalf("NewJSONStorage returned error: %v", err)
+	}
+	if s.path != "storage.json" {
+		t.Fatalf("path = %q, want %q", s.path, "storage.json")
+	}
+}
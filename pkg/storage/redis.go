@@ -0,0 +1,92 @@
+package storage
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/dlopes7/dynatrace-ca-sdm-bridge/pkg/sdm"
+	"github.com/go-redis/redis/v8"
+)
+
+// RedisConfig configures the Redis-backed Storage implementation.
+type RedisConfig struct {
+	Addr      string `json:"addr"`
+	Password  string `json:"password"`
+	DB        int    `json:"db"`
+	KeyPrefix string `json:"keyPrefix"`
+}
+
+// RedisStorage is a Storage backed by Redis, suitable for running several
+// bridge instances against the same state.
+type RedisStorage struct {
+	client *redis.Client
+	prefix string
+	ctx    context.Context
+}
+
+// NewRedisStorage connects to cfg.Addr and returns a RedisStorage.
+func NewRedisStorage(cfg RedisConfig) (*RedisStorage, error) {
+	prefix := cfg.KeyPrefix
+	if prefix == "" {
+		prefix = "sdm:ticket:"
+	}
+
+	client := redis.NewClient(&redis.Options{
+		Addr:     cfg.Addr,
+		Password: cfg.Password,
+		DB:       cfg.DB,
+	})
+
+	ctx := context.Background()
+	if err := client.Ping(ctx).Err(); err != nil {
+		return nil, fmt.Errorf("could not connect to redis storage: %w", err)
+	}
+
+	return &RedisStorage{client: client, prefix: prefix, ctx: ctx}, nil
+}
+
+func (s *RedisStorage) key(problemID string) string {
+	return s.prefix + problemID
+}
+
+func (s *RedisStorage) Get(problemID string) (*sdm.CreateRequestResponse, error) {
+	val, err := s.client.Get(s.ctx, s.key(problemID)).Bytes()
+	if err == redis.Nil {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var ticket sdm.CreateRequestResponse
+	if err := json.Unmarshal(val, &ticket); err != nil {
+		return nil, err
+	}
+	return &ticket, nil
+}
+
+func (s *RedisStorage) Put(problemID string, ticket *sdm.CreateRequestResponse) error {
+	val, err := json.Marshal(ticket)
+	if err != nil {
+		return err
+	}
+	return s.client.Set(s.ctx, s.key(problemID), val, 0).Err()
+}
+
+func (s *RedisStorage) Delete(problemID string) error {
+	return s.client.Del(s.ctx, s.key(problemID)).Err()
+}
+
+func (s *RedisStorage) List() ([]string, error) {
+	keys, err := s.client.Keys(s.ctx, s.prefix+"*").Result()
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make([]string, 0, len(keys))
+	for _, k := range keys {
+		ids = append(ids, k[len(s.prefix):])
+	}
+	return ids, nil
+}
@@ -0,0 +1,95 @@
+package storage
+
+import (
+	"fmt"
+
+	"github.com/dlopes7/dynatrace-ca-sdm-bridge/pkg/sdm"
+	"gorm.io/driver/postgres"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+// SQLConfig configures the GORM-backed Storage implementation.
+type SQLConfig struct {
+	Driver string `json:"driver"` // "postgres" or "sqlite"
+	DSN    string `json:"dsn"`
+}
+
+// ticketRecord is the GORM model backing SQLStorage.
+type ticketRecord struct {
+	ProblemID        string `gorm:"primaryKey"`
+	NewRequestHandle string
+	NewRequestNumber string
+}
+
+// SQLStorage is a Storage backed by a SQL database via GORM.
+type SQLStorage struct {
+	db *gorm.DB
+}
+
+// NewSQLStorage opens cfg.DSN with the requested driver and migrates the
+// ticketRecord table.
+func NewSQLStorage(cfg SQLConfig) (*SQLStorage, error) {
+	var dialector gorm.Dialector
+	switch cfg.Driver {
+	case "", "postgres":
+		dialector = postgres.Open(cfg.DSN)
+	case "sqlite":
+		dialector = sqlite.Open(cfg.DSN)
+	default:
+		return nil, fmt.Errorf("unknown sql storage driver %q, options are postgres, sqlite", cfg.Driver)
+	}
+
+	db, err := gorm.Open(dialector, &gorm.Config{})
+	if err != nil {
+		return nil, fmt.Errorf("could not open sql storage: %w", err)
+	}
+
+	if err := db.AutoMigrate(&ticketRecord{}); err != nil {
+		return nil, fmt.Errorf("could not migrate sql storage: %w", err)
+	}
+
+	return &SQLStorage{db: db}, nil
+}
+
+func (s *SQLStorage) Get(problemID string) (*sdm.CreateRequestResponse, error) {
+	var record ticketRecord
+	err := s.db.First(&record, "problem_id = ?", problemID).Error
+	if err == gorm.ErrRecordNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &sdm.CreateRequestResponse{
+		NewRequestHandle: record.NewRequestHandle,
+		NewRequestNumber: record.NewRequestNumber,
+	}, nil
+}
+
+func (s *SQLStorage) Put(problemID string, ticket *sdm.CreateRequestResponse) error {
+	record := ticketRecord{
+		ProblemID:        problemID,
+		NewRequestHandle: ticket.NewRequestHandle,
+		NewRequestNumber: ticket.NewRequestNumber,
+	}
+	return s.db.Save(&record).Error
+}
+
+func (s *SQLStorage) Delete(problemID string) error {
+	return s.db.Delete(&ticketRecord{}, "problem_id = ?", problemID).Error
+}
+
+func (s *SQLStorage) List() ([]string, error) {
+	var records []ticketRecord
+	if err := s.db.Select("problem_id").Find(&records).Error; err != nil {
+		return nil, err
+	}
+
+	ids := make([]string, 0, len(records))
+	for _, r := range records {
+		ids = append(ids, r.ProblemID)
+	}
+	return ids, nil
+}
@@ -0,0 +1,41 @@
+// Package storage persists the CA SDM ticket opened for each Dynatrace
+// problem so a later RESOLVED webhook can look the ticket back up.
+package storage
+
+import (
+	"fmt"
+
+	"github.com/dlopes7/dynatrace-ca-sdm-bridge/pkg/sdm"
+)
+
+// Storage abstracts how open tickets are persisted so that the webhook
+// package does not need to know whether it is backed by a file, a SQL
+// database or Redis.
+type Storage interface {
+	Get(problemID string) (*sdm.CreateRequestResponse, error)
+	Put(problemID string, ticket *sdm.CreateRequestResponse) error
+	Delete(problemID string) error
+	List() ([]string, error)
+}
+
+// Config selects and configures a Storage implementation.
+type Config struct {
+	Type  string      `json:"type"` // "json" (default), "sql" or "redis"
+	JSON  JSONConfig  `json:"json"`
+	SQL   SQLConfig   `json:"sql"`
+	Redis RedisConfig `json:"redis"`
+}
+
+// New builds the Storage implementation selected by cfg.Type.
+func New(cfg Config) (Storage, error) {
+	switch cfg.Type {
+	case "", "json":
+		return NewJSONStorage(cfg.JSON)
+	case "sql":
+		return NewSQLStorage(cfg.SQL)
+	case "redis":
+		return NewRedisStorage(cfg.Redis)
+	default:
+		return nil, fmt.Errorf("unknown storage type %q, options are json, sql, redis", cfg.Type)
+	}
+}
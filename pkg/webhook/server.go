@@ -0,0 +1,205 @@
+// Package webhook exposes the HTTP server that receives Dynatrace problem
+// webhooks and turns them into queued CA SDM ticket operations.
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/dlopes7/dynatrace-ca-sdm-bridge/pkg/config"
+	"github.com/dlopes7/dynatrace-ca-sdm-bridge/pkg/queue"
+	"github.com/dlopes7/dynatrace-ca-sdm-bridge/pkg/sdm"
+	"github.com/dlopes7/dynatrace-ca-sdm-bridge/pkg/storage"
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+type contextKey string
+
+const requestIDKey contextKey = "request_id"
+
+// Response is the JSON body returned by /sdm, and by /jobs/{id} and /readyz
+// on error; /jobs/{id} otherwise returns a queue.JobStatus.
+type Response struct {
+	Error   bool   `json:"error"`
+	Message string `json:"message"`
+	JobID   string `json:"jobId,omitempty"`
+}
+
+// Server handles Dynatrace problem webhooks. It is an http.Handler, so tests
+// can exercise SDMHandler with a fake queue.Queue instead of a real SOAP
+// endpoint; ReadyzHandler calls the concrete *sdm.Client values held in the
+// ClientSet.
+type Server struct {
+	sdmClients *sdm.ClientSet
+	store      storage.Storage
+	queue      queue.Queue
+
+	mu  sync.RWMutex
+	cfg config.Config
+
+	router *mux.Router
+}
+
+// NewServer wires together the dependencies SDMHandler needs and returns the
+// http.Handler that should be passed to http.Server. sdmClients holds one
+// *sdm.Client per configured profile name (see config.Config.SDMProfiles),
+// and is shared with the queue's TaskHandler so a SIGHUP reload that swaps
+// it takes effect for both.
+func NewServer(cfg config.Config, sdmClients *sdm.ClientSet, store storage.Storage, q queue.Queue) *Server {
+	s := &Server{sdmClients: sdmClients, store: store, queue: q, cfg: cfg}
+
+	router := mux.NewRouter()
+	router.HandleFunc("/sdm", s.SDMHandler).Methods("POST")
+	router.HandleFunc("/jobs/{id}", s.JobHandler).Methods("GET")
+	router.HandleFunc("/healthz", s.HealthzHandler).Methods("GET")
+	router.HandleFunc("/readyz", s.ReadyzHandler).Methods("GET")
+	router.Handle("/metrics", promhttp.Handler()).Methods("GET")
+	router.Use(s.loggingMiddleware)
+	s.router = router
+
+	return s
+}
+
+// ServeHTTP lets Server be used directly as an http.Handler.
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.router.ServeHTTP(w, r)
+}
+
+// SetConfig swaps in a reloaded configuration, e.g. after a SIGHUP. The
+// storage and queue backends are not reloaded: switching them out from
+// under in-flight jobs isn't safe, so changes to cfg.Storage/cfg.Queue
+// still require a restart. The SDM profiles/mapping reload instead through
+// the shared *sdm.ClientSet passed to NewServer.
+func (s *Server) SetConfig(cfg config.Config) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.cfg = cfg
+}
+
+func (s *Server) Config() config.Config {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.cfg
+}
+
+// loggingMiddleware stamps every request with a request ID, propagated via
+// context.Context so downstream log lines can be correlated.
+func (s *Server) loggingMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requestID := uuid.NewString()
+		ctx := context.WithValue(r.Context(), requestIDKey, requestID)
+		r = r.WithContext(ctx)
+
+		start := time.Now()
+		slog.InfoContext(ctx, "request received", "request_id", requestID, "method", r.Method, "url", r.RequestURI, "remote_addr", r.RemoteAddr, "content_length", r.ContentLength)
+		next.ServeHTTP(w, r)
+		slog.InfoContext(ctx, "request handled", "request_id", requestID, "duration_ms", time.Since(start).Milliseconds())
+	})
+}
+
+func requestIDFrom(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey).(string)
+	return id
+}
+
+// SDMHandler enqueues a sdm:open_ticket or sdm:close_ticket task and returns
+// immediately with a job ID; it no longer waits on the SOAP calls itself, so
+// the Dynatrace webhook connection isn't held open while CA SDM is slow.
+func (s *Server) SDMHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	resp := Response{}
+
+	decoder := json.NewDecoder(r.Body)
+	var problem sdm.Problem
+
+	err := decoder.Decode(&problem)
+	if err != nil {
+		slog.ErrorContext(ctx, "could not parse the problem from the request body", "request_id", requestIDFrom(ctx), "error", err.Error())
+		w.WriteHeader(http.StatusBadRequest)
+		resp = Response{
+			Error:   true,
+			Message: fmt.Sprintf("Could not parse the problem from the request body: %s", err.Error()),
+		}
+		json.NewEncoder(w).Encode(resp)
+		return
+	}
+
+	slog.DebugContext(ctx, "parsed problem", "request_id", requestIDFrom(ctx), "problem_id", problem.ProblemID, "state", problem.State)
+
+	var taskType string
+	var payload interface{}
+
+	switch problem.State {
+	case "OPEN":
+		taskType = queue.TaskTypeOpenTicket
+		payload = queue.OpenTicketPayload{Problem: problem}
+	case "RESOLVED":
+		taskType = queue.TaskTypeCloseTicket
+		payload = queue.CloseTicketPayload{Problem: problem}
+	default:
+		slog.DebugContext(ctx, "ignoring problem in unhandled state", "request_id", requestIDFrom(ctx), "problem_id", problem.ProblemID, "state", problem.State)
+		json.NewEncoder(w).Encode(resp)
+		return
+	}
+
+	jobID, err := s.queue.Enqueue(taskType, payload)
+	if err != nil {
+		slog.ErrorContext(ctx, "could not enqueue task", "request_id", requestIDFrom(ctx), "sdm_operation", taskType, "problem_id", problem.ProblemID, "error", err.Error())
+		w.WriteHeader(http.StatusInternalServerError)
+		resp = Response{
+			Error:   true,
+			Message: fmt.Sprintf("Could not enqueue %s: %s", taskType, err.Error()),
+		}
+		json.NewEncoder(w).Encode(resp)
+		return
+	}
+
+	slog.InfoContext(ctx, "enqueued task", "request_id", requestIDFrom(ctx), "sdm_operation", taskType, "problem_id", problem.ProblemID, "job_id", jobID)
+	w.WriteHeader(http.StatusAccepted)
+	json.NewEncoder(w).Encode(Response{
+		Error:   false,
+		Message: fmt.Sprintf("Enqueued %s", taskType),
+		JobID:   jobID,
+	})
+}
+
+// JobHandler reports the state of a task previously enqueued by SDMHandler.
+func (s *Server) JobHandler(w http.ResponseWriter, r *http.Request) {
+	jobID := mux.Vars(r)["id"]
+
+	status, err := s.queue.JobStatus(jobID)
+	if err != nil {
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(Response{Error: true, Message: err.Error()})
+		return
+	}
+
+	json.NewEncoder(w).Encode(status)
+}
+
+// HealthzHandler reports liveness: the process is up and serving requests.
+func (s *Server) HealthzHandler(w http.ResponseWriter, r *http.Request) {
+	w.WriteHeader(http.StatusOK)
+}
+
+// ReadyzHandler reports readiness by probing every configured CA SDM profile
+// with a cached login call, so Kubernetes can drain the pod cleanly when any
+// tenant's CA SDM is down.
+func (s *Server) ReadyzHandler(w http.ResponseWriter, r *http.Request) {
+	for profile, client := range s.sdmClients.Get() {
+		if err := client.Healthy(); err != nil {
+			slog.WarnContext(r.Context(), "readiness probe failed", "request_id", requestIDFrom(r.Context()), "profile", profile, "error", err.Error())
+			w.WriteHeader(http.StatusServiceUnavailable)
+			json.NewEncoder(w).Encode(Response{Error: true, Message: fmt.Sprintf("SDM login probe failed for profile %q: %s", profile, err.Error())})
+			return
+		}
+	}
+	w.WriteHeader(http.StatusOK)
+}
@@ -0,0 +1,124 @@
+package webhook
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/dlopes7/dynatrace-ca-sdm-bridge/pkg/config"
+	"github.com/dlopes7/dynatrace-ca-sdm-bridge/pkg/queue"
+	"github.com/dlopes7/dynatrace-ca-sdm-bridge/pkg/sdm"
+)
+
+// fakeQueue is a queue.Queue that records every Enqueue call instead of
+// running a real task, so SDMHandler can be tested without a CA SDM
+// endpoint or a worker goroutine.
+type fakeQueue struct {
+	enqueued []fakeTask
+	err      error
+}
+
+type fakeTask struct {
+	taskType string
+	payload  interface{}
+}
+
+func (q *fakeQueue) Enqueue(taskType string, payload interface{}) (string, error) {
+	if q.err != nil {
+		return "", q.err
+	}
+	q.enqueued = append(q.enqueued, fakeTask{taskType: taskType, payload: payload})
+	return fmt.Sprintf("fake-%d", len(q.enqueued)), nil
+}
+
+func (q *fakeQueue) JobStatus(jobID string) (*queue.JobStatus, error) {
+	return &queue.JobStatus{ID: jobID, State: queue.JobStatePending}, nil
+}
+
+func postProblem(t *testing.T, s *Server, problem sdm.Problem) *httptest.ResponseRecorder {
+	t.Helper()
+	body, err := json.Marshal(problem)
+	if err != nil {
+		t.Fatalf("could not marshal problem: %v", err)
+	}
+
+	req := httptest.NewRequest(http.MethodPost, "/sdm", bytes.NewReader(body))
+	rec := httptest.NewRecorder()
+	s.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestSDMHandlerEnqueuesOpenTicket(t *testing.T) {
+	q := &fakeQueue{}
+	s := NewServer(config.Config{}, sdm.NewClientSet(nil), nil, q)
+
+	rec := postProblem(t, s, sdm.Problem{ProblemID: "P-1", State: "OPEN"})
+
+	if rec.Code != http.StatusAccepted {
+		t.Fatalf("status = %d, want %d", rec.Code, http.StatusAccepted)
+	}
+	if len(q.enqueued) != 1 {
+		t.Fatalf("got %d enqueued tasks, want 1", len(q.enqueued))
+	}
+	if q.enqueued[0].taskType != queue.TaskTypeOpenTicket {
+		t.Errorf("taskType = %q, want %q", q.enqueued[0].taskType, queue.TaskTypeOpenTicket)
+	}
+
+	var resp Response
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("could not decode response: %v", err)
+	}
+	if resp.Error || resp.JobID == "" {
+		t.Errorf("resp = %+v, want a successful response with a job ID", resp)
+	}
+}
+
+func TestSDMHandlerEnqueuesCloseTicket(t *testing.T) {
+	q := &fakeQueue{}
+	s := NewServer(config.Config{}, sdm.NewClientSet(nil), nil, q)
+
+	postProblem(t, s, sdm.Problem{ProblemID: "P-1", State: "RESOLVED"})
+
+	if len(q.enqueued) != 1 {
+		t.Fatalf("got %d enqueued tasks, want 1", len(q.enqueued))
+	}
+	if q.enqueued[0].taskType != queue.TaskTypeCloseTicket {
+		t.Errorf("taskType = %q, want %q", q.enqueued[0].taskType, queue.TaskTypeCloseTicket)
+	}
+}
+
+func TestSDMHandlerIgnoresUnhandledState(t *testing.T) {
+	q := &fakeQueue{}
+	s := NewServer(config.Config{}, sdm.NewClientSet(nil), nil, q)
+
+	rec := postProblem(t, s, sdm.Problem{ProblemID: "P-1", State: "INFO"})
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d", rec.Code, http.StatusOK)
+	}
+	if len(q.enqueued) != 0 {
+		t.Fatalf("got %d enqueued tasks, want 0", len(q.enqueued))
+	}
+}
+
+func TestSDMHandlerEnqueueFailure(t *testing.T) {
+	q := &fakeQueue{err: fmt.Errorf("queue is full")}
+	s := NewServer(config.Config{}, sdm.NewClientSet(nil), nil, q)
+
+	rec := postProblem(t, s, sdm.Problem{ProblemID: "P-1", State: "OPEN"})
+
+	if rec.Code != http.StatusInternalServerError {
+		t.Fatalf("status = %d, want %d", rec.Code, http.StatusInternalServerError)
+	}
+
+	var resp Response
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("could not decode response: %v", err)
+	}
+	if !resp.Error {
+		t.Error("resp.Error = false, want true")
+	}
+}